@@ -3,43 +3,59 @@ package main
 import (
 	"context"
 	"fmt"
-	"github.com/go-micro/plugins/v4/client/grpc"
-	"github.com/go-micro/plugins/v4/registry/etcd"
-	"go-micro.dev/v4"
-	"go-micro.dev/v4/logger"
-	"go-micro.dev/v4/registry"
+	"log"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/resolver"
+
+	"user-service/pkg/discovery"
 	"user-service/proto/user"
 )
 
 func main() {
-	// 步骤 1：创建微服务客户端实例
-	etcdReg := etcd.NewRegistry(
-		registry.Addrs("127.0.0.1:2379"), // etcd 服务地址
-	)
-	service := micro.NewService(
-		micro.Client(grpc.NewClient()),
-		micro.Name("user.client"), // 客户端服务名称（仅用于标识）
-		micro.Registry(etcdReg),
+	// 步骤 1：创建Etcd客户端并注册自定义resolver
+	etcdClient, err := clientv3.New(clientv3.Config{
+		Endpoints:   []string{"127.0.0.1:2379"},
+		DialTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		log.Fatalf("创建Etcd客户端失败：%v", err)
+	}
+	defer etcdClient.Close()
+	resolver.Register(discovery.NewBuilder(etcdClient))
+
+	// 步骤 2：通过 etcd:///<服务名> 直连gRPC服务端，由resolver负责服务发现
+	conn, err := grpc.Dial(
+		"etcd:///user.service",
+		grpc.WithInsecure(),
+		grpc.WithDefaultServiceConfig(`{"loadBalancingPolicy":"round_robin"}`),
 	)
-	service.Init()
+	if err != nil {
+		log.Fatalf("连接user.service失败：%v", err)
+	}
+	defer conn.Close()
 
-	// 步骤 2：创建 UserService 客户端代理（通过服务名称 "user.service" 发现服务端）
-	userClient := user.NewUserService("user.service", service.Client())
+	// 步骤 3：创建 UserService 客户端代理
+	userClient := user.NewUserServiceClient(conn)
 
-	// 步骤 3：构造注册请求参数
+	// 步骤 4：构造注册请求参数
 	req := &user.RegisterRequest{
 		Username: "test_user_001",    // 用户名
 		Password: "123456Abc",        // 密码
 		Email:    "test@example.com", // 邮箱
 	}
 
-	// 步骤 4：调用服务端的 Register 方法
-	rsp, err := userClient.Register(context.Background(), req)
+	// 步骤 5：调用服务端的 Register 方法
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	rsp, err := userClient.Register(ctx, req)
 	if err != nil {
-		logger.Fatalf("调用注册接口失败：%v", err)
+		log.Fatalf("调用注册接口失败：%v", err)
 	}
 
-	// 步骤 5：打印响应结果
+	// 步骤 6：打印响应结果
 	fmt.Println("===== 注册响应结果 =====")
 	fmt.Printf("状态码：%d\n", rsp.Code)
 	fmt.Printf("提示信息：%s\n", rsp.Message)
@@ -1,54 +1,170 @@
 package middleware
 
 import (
+	"strconv"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
-	"time"
 	"user-service/pkg/common"
 )
 
-// 令牌桶限流中间件（基于Redis，支持分布式多实例限流）
-func RateLimit(redisClient *redis.Client, limit int64, interval time.Duration) gin.HandlerFunc {
+// tokenBucketScript 原子化令牌桶：KEYS[1]=桶key，ARGV=capacity,refill_per_sec,now_ms,requested
+// 读取hash字段tokens/ts，按流逝时间补充令牌，令牌足够则扣减并返回{allowed, tokens, retry_after_ms}，
+// 其中tokens为扣减后的剩余令牌数（供X-RateLimit-Remaining使用），
+// retry_after_ms为不足时还需等待多久才能凑够requested个令牌（供Retry-After使用，足够时为0）
+var tokenBucketScript = redis.NewScript(`
+local key = KEYS[1]
+local capacity = tonumber(ARGV[1])
+local refill_per_sec = tonumber(ARGV[2])
+local now_ms = tonumber(ARGV[3])
+local requested = tonumber(ARGV[4])
+
+local data = redis.call('HMGET', key, 'tokens', 'ts')
+local tokens = tonumber(data[1])
+local ts = tonumber(data[2])
+if tokens == nil then
+    tokens = capacity
+    ts = now_ms
+end
+
+local delta = math.max(0, now_ms - ts)
+local filled = math.min(capacity, tokens + delta * refill_per_sec / 1000)
+
+-- 并发请求的now_ms可能乱序到达，ts只前移不回退，避免同一段时间被重复补充令牌
+redis.call('HMSET', key, 'tokens', filled >= requested and (filled - requested) or filled, 'ts', math.max(ts, now_ms))
+redis.call('PEXPIRE', key, math.ceil(capacity / refill_per_sec * 1000) * 2)
+
+if filled >= requested then
+    return {1, math.floor(filled - requested), 0}
+end
+
+local retry_after_ms = math.ceil((requested - filled) / refill_per_sec * 1000)
+return {0, math.floor(filled), retry_after_ms}
+`)
+
+// slidingWindowScript 滑动窗口日志：KEYS[1]=窗口key，ARGV=now_ms,window_ms,limit
+// 清理窗口外的旧请求，统计窗口内请求数，未超限则记录本次请求并返回1，否则返回0
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now_ms = tonumber(ARGV[1])
+local window_ms = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now_ms - window_ms)
+local count = redis.call('ZCARD', key)
+if count >= limit then
+    return 0
+end
+
+redis.call('ZADD', key, now_ms, now_ms .. '-' .. math.random())
+redis.call('PEXPIRE', key, window_ms)
+return 1
+`)
+
+// KeyExtractor 从请求中提取限流维度的标识，如IP、用户ID、接口路径组合
+type KeyExtractor func(c *gin.Context) string
+
+// CostFunc 计算单次请求消耗的令牌数，默认每次消耗1
+type CostFunc func(c *gin.Context) int64
+
+// OnLimitHandler 触发限流时的处理逻辑，可自定义响应内容
+type OnLimitHandler func(c *gin.Context)
+
+// RateLimitPolicy 令牌桶限流策略：不同路由可配置不同的容量、补充速率与限流维度
+type RateLimitPolicy struct {
+	Capacity     int64          // 桶容量（最大令牌数）
+	RefillPerSec int64          // 每秒补充的令牌数
+	KeyExtractor KeyExtractor   // 限流key提取函数，默认按IP+路径
+	Cost         CostFunc       // 单次请求消耗令牌数，默认1
+	OnLimit      OnLimitHandler // 触发限流时的响应，默认429
+}
+
+func defaultKeyExtractor(c *gin.Context) string {
+	return "rate_limit:token_bucket:" + c.FullPath() + ":" + c.ClientIP()
+}
+
+func defaultOnLimit(c *gin.Context) {
+	common.Fail(c, 429, "请求过于频繁，请稍后再试")
+	c.Abort()
+}
+
+// RateLimit 基于Redis Lua脚本的令牌桶限流中间件（单次EVAL原子执行，避免并发下的竞态）
+func RateLimit(redisClient *redis.Client, policy RateLimitPolicy) gin.HandlerFunc {
+	if policy.KeyExtractor == nil {
+		policy.KeyExtractor = defaultKeyExtractor
+	}
+	if policy.Cost == nil {
+		policy.Cost = func(c *gin.Context) int64 { return 1 }
+	}
+	if policy.OnLimit == nil {
+		policy.OnLimit = defaultOnLimit
+	}
+
 	return func(c *gin.Context) {
-		// 1. 获取限流标识（此处用客户端IP，后续登录后可改为用户ID）
-		clientIP := c.ClientIP()
-		// 接口路径作为限流维度，实现接口粒度限流
-		apiPath := c.FullPath()
-		limitKey := "rate_limit:" + apiPath + ":" + clientIP
-
-		// 2. Redis令牌桶核心逻辑（原子操作，避免并发问题）
-		// 2.1 初始化令牌桶（若不存在，设置初始令牌数和过期时间）
-		_, err := redisClient.SetNX(c, limitKey+":last_refill", time.Now().Unix(), interval*2).Result()
+		key := policy.KeyExtractor(c)
+		nowMs := time.Now().UnixMilli()
+
+		result, err := tokenBucketScript.Run(c, redisClient, []string{key},
+			policy.Capacity, policy.RefillPerSec, nowMs, policy.Cost(c)).Slice()
 		if err != nil {
-			common.Error(c, "限流初始化失败")
+			common.Error(c, "限流检查失败")
 			c.Abort()
 			return
 		}
+		allowed, remaining, retryAfterMs := result[0].(int64), result[1].(int64), result[2].(int64)
+
+		retryAfterSec := (retryAfterMs + 999) / 1000
+
+		c.Header("X-RateLimit-Limit", strconv.FormatInt(policy.Capacity, 10))
+		c.Header("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(time.Now().Unix()+retryAfterSec, 10))
 
-		// 2.2 计算时间差，补充令牌（令牌生成速度=limit/interval）
-		lastRefill, _ := redisClient.Get(c, limitKey+":last_refill").Int64()
-		now := time.Now().Unix()
-		elapsed := now - lastRefill
-		if elapsed > 0 {
-			// 计算应补充的令牌数
-			tokensToAdd := (elapsed * limit) / int64(interval.Seconds())
-			if tokensToAdd > 0 {
-				// 原子递增令牌数，且不超过最大限制
-				redisClient.IncrBy(c, limitKey, tokensToAdd)
-				redisClient.Set(c, limitKey+":last_refill", now, interval*2)
-			}
+		if allowed == 0 {
+			c.Header("Retry-After", strconv.FormatInt(retryAfterSec, 10))
+			policy.OnLimit(c)
+			return
 		}
+		c.Next()
+	}
+}
 
-		// 2.3 尝试获取令牌（原子递减）
-		tokens, _ := redisClient.Decr(c, limitKey).Result()
-		if tokens < 0 {
-			// 无令牌，返回限流响应
-			common.Fail(c, 429, "请求过于频繁，请稍后再试")
+// SlidingWindowPolicy 滑动窗口限流策略，适用于需要严格公平性（不允许突发）的接口
+type SlidingWindowPolicy struct {
+	Limit        int64         // 窗口内允许的最大请求数
+	Window       time.Duration // 窗口长度
+	KeyExtractor KeyExtractor  // 限流key提取函数，默认按IP+路径
+	OnLimit      OnLimitHandler
+}
+
+// SlidingWindowRateLimit 基于ZSET实现的滑动窗口日志限流中间件
+func SlidingWindowRateLimit(redisClient *redis.Client, policy SlidingWindowPolicy) gin.HandlerFunc {
+	if policy.KeyExtractor == nil {
+		policy.KeyExtractor = func(c *gin.Context) string {
+			return "rate_limit:sliding_window:" + c.FullPath() + ":" + c.ClientIP()
+		}
+	}
+	if policy.OnLimit == nil {
+		policy.OnLimit = defaultOnLimit
+	}
+
+	return func(c *gin.Context) {
+		key := policy.KeyExtractor(c)
+		nowMs := time.Now().UnixMilli()
+		windowMs := policy.Window.Milliseconds()
+
+		allowed, err := slidingWindowScript.Run(c, redisClient, []string{key},
+			nowMs, windowMs, policy.Limit).Int()
+		if err != nil {
+			common.Error(c, "限流检查失败")
 			c.Abort()
 			return
 		}
 
-		// 3. 有令牌，继续执行后续中间件/处理器
+		if allowed == 0 {
+			policy.OnLimit(c)
+			return
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// TestRateLimit_TokenBucket_ConcurrentAccepted 并发压测令牌桶：在capacity固定、
+// 持续refillPerSec补充的情况下，总放行数应收敛到 capacity + elapsed*refillPerSec（±1个令牌误差），
+// 验证Lua脚本的原子扣减在并发下不会多放行或漏放行
+func TestRateLimit_TokenBucket_ConcurrentAccepted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer redisClient.Close()
+
+	const capacity = 50
+	const refillPerSec = 200
+	const workers = 32
+	const requestsPerWorker = 500
+
+	handler := RateLimit(redisClient, RateLimitPolicy{
+		Capacity:     capacity,
+		RefillPerSec: refillPerSec,
+		KeyExtractor: func(c *gin.Context) string { return "test:token_bucket" },
+	})
+
+	var accepted int64
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < requestsPerWorker; j++ {
+				w := httptest.NewRecorder()
+				c, _ := gin.CreateTestContext(w)
+				c.Request = httptest.NewRequest(http.MethodGet, "/probe", nil)
+				handler(c)
+				if !c.IsAborted() {
+					atomic.AddInt64(&accepted, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	expected := int64(capacity) + int64(elapsed.Seconds()*refillPerSec)
+	got := atomic.LoadInt64(&accepted)
+	if got < expected-1 || got > expected+1 {
+		t.Fatalf("放行数量超出预期范围：got=%d, expected=%d±1", got, expected)
+	}
+}
@@ -1,49 +1,60 @@
 package config
 
 import (
+	"strings"
 	"time"
 
 	"github.com/afex/hystrix-go/hystrix"
+	"github.com/spf13/viper"
 )
 
-// 全局配置结构体（所有配置集中管理，后续可改为从 yaml/viper 读取）
+// 全局配置结构体（所有配置集中管理，通过Viper从 config.yaml + 环境变量加载）
 type GlobalConfig struct {
-	Etcd    EtcdConfig    `json:"etcd"`
-	Hystrix HystrixConfig `json:"hystrix"`
-	Gin     GinConfig     `json:"gin"`
-	Service ServiceConfig `json:"service"`
+	Etcd    EtcdConfig    `mapstructure:"etcd"`
+	Hystrix HystrixConfig `mapstructure:"hystrix"`
+	Gin     GinConfig     `mapstructure:"gin"`
+	Service ServiceConfig `mapstructure:"service"`
+	Otel    OtelConfig    `mapstructure:"otel"`
 }
 
 // Etcd 配置
 type EtcdConfig struct {
-	Adders []string `json:"addrs"` // Etcd 地址列表（集群支持）
+	Adders []string `mapstructure:"addrs"` // Etcd 地址列表（集群支持）
 }
 
 // Hystrix 熔断降级配置（针对用户服务）
 type HystrixConfig struct {
-	CommandName            string `json:"command_name"`
-	Timeout                int    `json:"timeout"`                  // 超时时间（毫秒）
-	MaxConcurrentRequests  int    `json:"max_concurrent_requests"`  // 最大并发
-	ErrorPercentThreshold  int    `json:"error_percent_threshold"`  // 错误率阈值
-	SleepWindow            int    `json:"sleep_window"`             // 熔断休眠窗口（毫秒）
-	RequestVolumeThreshold int    `json:"request_volume_threshold"` // 最小触发请求数
+	CommandName            string `mapstructure:"command_name"`
+	Timeout                int    `mapstructure:"timeout"`                  // 超时时间（毫秒）
+	MaxConcurrentRequests  int    `mapstructure:"max_concurrent_requests"`  // 最大并发
+	ErrorPercentThreshold  int    `mapstructure:"error_percent_threshold"`  // 错误率阈值
+	SleepWindow            int    `mapstructure:"sleep_window"`             // 熔断休眠窗口（毫秒）
+	RequestVolumeThreshold int    `mapstructure:"request_volume_threshold"` // 最小触发请求数
 }
 
 // Gin Web 服务配置
 type GinConfig struct {
-	Port string `json:"port"` // 监听端口
-	Mode string `json:"mode"` // 运行模式（debug/release）
+	Port string `mapstructure:"port"` // 监听端口
+	Mode string `mapstructure:"mode"` // 运行模式（debug/release）
 }
 
 // 微服务配置
 type ServiceConfig struct {
-	UserName string        `json:"user_name"` // 用户服务名称
-	Version  string        `json:"version"`   // 服务版本
-	Timeout  time.Duration `json:"timeout"`   // 服务端处理超时
+	UserName        string        `mapstructure:"user_name"`        // 用户服务名称
+	Version         string        `mapstructure:"version"`          // 服务版本
+	Addr            string        `mapstructure:"addr"`             // gRPC监听地址
+	Timeout         time.Duration `mapstructure:"timeout"`          // 服务端处理超时
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"` // 优雅停机等待在途请求的超时时间
 }
 
-// 初始化全局默认配置（后续可替换为配置文件读取）
-func InitGlobalConfig() *GlobalConfig {
+// OtelConfig OpenTelemetry 链路追踪配置
+type OtelConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`
+	OtlpEndpoint string `mapstructure:"otlp_endpoint"` // OTLP collector地址，如 "localhost:4317"
+}
+
+// defaults 在未提供 config.yaml / 环境变量时使用的默认值
+func defaults() *GlobalConfig {
 	return &GlobalConfig{
 		Etcd: EtcdConfig{
 			Adders: []string{"127.0.0.1:2379"},
@@ -61,13 +72,58 @@ func InitGlobalConfig() *GlobalConfig {
 			Mode: "debug",
 		},
 		Service: ServiceConfig{
-			UserName: "user.service",
-			Version:  "v1.0.0",
-			Timeout:  3 * time.Second,
+			UserName:        "user.service",
+			Version:         "v1.0.0",
+			Addr:            "127.0.0.1:9000",
+			Timeout:         3 * time.Second,
+			ShutdownTimeout: 10 * time.Second,
+		},
+		Otel: OtelConfig{
+			Enabled:      false,
+			OtlpEndpoint: "localhost:4317",
 		},
 	}
 }
 
+// InitGlobalConfig 初始化全局默认配置，不读取任何配置文件，供未迁移到LoadConfig的调用方过渡使用
+//
+// Deprecated: 使用 LoadConfig 从 config.yaml / 环境变量加载配置
+func InitGlobalConfig() *GlobalConfig {
+	return defaults()
+}
+
+// LoadConfig 通过Viper加载配置：先写入默认值，再叠加 configPath 指向的 yaml 文件，
+// configPath 为空时读取当前目录下的 config.yaml（找不到则只用默认值）。
+// 环境变量（如 USER_SERVICE_ETCD_ADDRS）只覆盖配置文件中出现过的键，没有配置文件时环境变量不生效
+func LoadConfig(configPath string) (*GlobalConfig, error) {
+	cfg := defaults()
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.SetEnvPrefix("USER_SERVICE")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	if configPath != "" {
+		v.SetConfigFile(configPath)
+	} else {
+		v.SetConfigName("config")
+		v.AddConfigPath(".")
+	}
+
+	if err := v.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return nil, err
+		}
+		// 没有配置文件时，继续使用默认值（Viper此时不知道任何键，环境变量不会被读取）
+	}
+
+	if err := v.Unmarshal(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
 // 初始化 Hystrix 配置
 func InitHystrixConfig(hc HystrixConfig) {
 	hystrix.ConfigureCommand(hc.CommandName, hystrix.CommandConfig{
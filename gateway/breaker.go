@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"user-service/config"
+	"user-service/proto/user"
+)
+
+// commandName 按 "服务.方法" 生成Hystrix命令名，与 config.HystrixConfig.CommandName 的命名方式保持一致
+func commandName(service, method string) string {
+	return service + "." + method
+}
+
+// fallbackFunc 降级处理函数：接收调用失败/熔断/超时/拒绝的错误，返回一个降级响应
+type fallbackFunc[Rsp any] func(ctx context.Context, err error) (*Rsp, error)
+
+// callWithBreaker 用Hystrix包裹一次outbound RPC调用：正常时执行fn，
+// fn返回错误、触发熔断、超时或并发拒绝时执行fallback，返回降级结果而不是把错误原样透传给调用方
+func callWithBreaker[Rsp any](ctx context.Context, cfg config.HystrixConfig, service, method string, fn func() (*Rsp, error), fallback fallbackFunc[Rsp]) (*Rsp, error) {
+	cmd := commandName(service, method)
+	hystrix.ConfigureCommand(cmd, hystrix.CommandConfig{
+		Timeout:                cfg.Timeout,
+		MaxConcurrentRequests:  cfg.MaxConcurrentRequests,
+		ErrorPercentThreshold:  cfg.ErrorPercentThreshold,
+		SleepWindow:            cfg.SleepWindow,
+		RequestVolumeThreshold: cfg.RequestVolumeThreshold,
+	})
+
+	var rsp *Rsp
+	resultErr := hystrix.Do(cmd, func() error {
+		var err error
+		rsp, err = fn()
+		return err
+	}, func(err error) error {
+		fallbackRsp, fbErr := fallback(ctx, err)
+		if fbErr != nil {
+			return fbErr
+		}
+		rsp = fallbackRsp
+		return nil
+	})
+	if resultErr != nil {
+		return nil, resultErr
+	}
+	return rsp, nil
+}
+
+// registerDegradedResponse Register接口的降级响应，对应 common.ApiResponse{Code:2, Message:"服务降级"}
+func registerDegradedResponse(_ context.Context, err error) (*user.RegisterResponse, error) {
+	log.Printf("user.service.Register 触发熔断降级：%v", err)
+	return &user.RegisterResponse{
+		Code:    2, // common.ApiResponse 约定 2 为服务降级
+		Message: "服务降级",
+	}, nil
+}
+
+// startHystrixMetricsServer 启动Hystrix指标流服务（SSE），监听admin端口供Hystrix Dashboard观测熔断器状态
+func startHystrixMetricsServer(addr string) {
+	streamHandler := hystrix.NewStreamHandler()
+	streamHandler.Start()
+	go func() {
+		if err := http.ListenAndServe(addr, streamHandler); err != nil {
+			log.Printf("Hystrix指标服务启动失败：%v", err)
+		}
+	}()
+}
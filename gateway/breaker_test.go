@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"user-service/config"
+)
+
+// fakeRsp 用于驱动callWithBreaker泛型的测试响应类型
+type fakeRsp struct{ Value string }
+
+func fakeFallback(_ context.Context, _ error) (*fakeRsp, error) {
+	return &fakeRsp{Value: "degraded"}, nil
+}
+
+// TestCallWithBreaker_OpenHalfOpenClosed 验证熔断器在连续失败后打开（fallback接管），
+// 休眠窗口过后进入half-open试探，试探成功后恢复closed（重新放行真实调用）
+func TestCallWithBreaker_OpenHalfOpenClosed(t *testing.T) {
+	cfg := config.HystrixConfig{
+		Timeout:                50,
+		MaxConcurrentRequests:  10,
+		ErrorPercentThreshold:  50,
+		SleepWindow:            200,
+		RequestVolumeThreshold: 4,
+	}
+	service, method := "test.breaker", fmt.Sprintf("transitions-%d", time.Now().UnixNano())
+	cmd := commandName(service, method)
+
+	failingCall := func() (*fakeRsp, error) { return nil, errors.New("downstream挂了") }
+	succeedingCall := func() (*fakeRsp, error) { return &fakeRsp{Value: "ok"}, nil }
+
+	// 1. 连续失败，超过RequestVolumeThreshold且错误率超阈值后应触发打开
+	for i := 0; i < cfg.RequestVolumeThreshold+2; i++ {
+		rsp, err := callWithBreaker(context.Background(), cfg, service, method, failingCall, fakeFallback)
+		if err != nil {
+			t.Fatalf("未打开时fallback不应返回错误: %v", err)
+		}
+		if rsp.Value != "degraded" && i == cfg.RequestVolumeThreshold+1 {
+			t.Fatalf("预期已触发降级响应，实际: %+v", rsp)
+		}
+	}
+
+	cb, _, err := hystrix.GetCircuit(cmd)
+	if err != nil {
+		t.Fatalf("获取熔断器状态失败: %v", err)
+	}
+	if !cb.IsOpen() {
+		t.Fatal("连续失败超过阈值后，熔断器应处于open状态")
+	}
+
+	// 2. 等待SleepWindow，熔断器进入half-open，允许一次试探请求通过
+	time.Sleep(time.Duration(cfg.SleepWindow+50) * time.Millisecond)
+
+	rsp, err := callWithBreaker(context.Background(), cfg, service, method, succeedingCall, fakeFallback)
+	if err != nil {
+		t.Fatalf("half-open试探请求不应返回错误: %v", err)
+	}
+	if rsp.Value != "ok" {
+		t.Fatalf("half-open试探成功后应返回真实响应，实际: %+v", rsp)
+	}
+	if cb.IsOpen() {
+		t.Fatal("half-open试探成功后，熔断器应恢复closed状态")
+	}
+}
@@ -3,60 +3,92 @@ package main
 import (
 	"context"
 	"github.com/gin-gonic/gin"
-	"github.com/go-micro/plugins/v4/client/grpc"
-	"github.com/go-micro/plugins/v4/registry/etcd"
-	"go-micro.dev/v4"
-	"go-micro.dev/v4/registry"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/resolver"
+	"log"
 	"net/http"
 	"time"
+	"user-service/config"
+	"user-service/pkg/bootstrap"
+	"user-service/pkg/discovery"
 	"user-service/proto/user" // 导入你的 Protobuf 生成代码
 )
 
-// 全局变量：创建 user.service 的 RPC 客户端代理（复用 go-micro 客户端）
-var userRpcClient user.UserService
+// 全局变量：Etcd客户端（被resolver和健康检查复用）、user.service 的 gRPC 客户端代理、熔断配置
+var (
+	etcdClient    *clientv3.Client
+	userRpcClient user.UserServiceClient
+	hystrixCfg    config.HystrixConfig
+)
 
-// 初始化 go-micro + Etcd + RPC 客户端
-func initMicro() {
-	// 1. 初始化 Etcd 注册中心（与原有 user.service 配置一致）
-	etcdReg := etcd.NewRegistry(
-		registry.Addrs("127.0.0.1:2379"),
+// 初始化 Etcd resolver + gRPC 客户端，替代原先基于 go-micro 注册中心的发现方式
+func initMicro(cfg *config.GlobalConfig) {
+	// 1. 初始化 Etcd 客户端并注册自定义 resolver
+	var err error
+	etcdClient, err = clientv3.New(clientv3.Config{
+		Endpoints:   cfg.Etcd.Adders,
+		DialTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		log.Fatalf("Etcd客户端初始化失败：%v", err)
+	}
+	resolver.Register(discovery.NewBuilder(etcdClient))
+
+	// 2. 通过 etcd:///user.service 直连服务端（resolver 负责地址发现与更新），
+	// 并挂上otel的stats handler让trace context随请求透传到服务端
+	conn, err := grpc.Dial(
+		"etcd:///"+cfg.Service.UserName,
+		grpc.WithInsecure(),
+		grpc.WithDefaultServiceConfig(`{"loadBalancingPolicy":"round_robin"}`),
+		bootstrap.ClientDialOption(),
 	)
+	if err != nil {
+		log.Fatalf("连接%s失败：%v", cfg.Service.UserName, err)
+	}
 
-	// 2. 初始化 go-micro 服务（仅用于创建 RPC 客户端，无需启动 Server）
-	microService := micro.NewService(
-		micro.Name("user.gin.gateway"), // 网关服务名称（标识作用）
-		micro.Registry(etcdReg),        // 绑定 Etcd 注册中心
-		micro.Client(grpc.NewClient()), // 绑定 gRPC 客户端（与 user.service 协议匹配）
-	)
-	microService.Init()
+	// 3. 创建 user.service 的 gRPC 客户端代理
+	userRpcClient = user.NewUserServiceClient(conn)
 
-	// 3. 创建 user.service 的 RPC 客户端代理（通过服务名发现）
-	userRpcClient = user.NewUserService("user.service", microService.Client())
+	// 4. 启动Hystrix指标流服务，供Dashboard观测熔断器状态
+	startHystrixMetricsServer(":8081")
 }
 
 func main() {
-	// 1. 初始化 go-micro + RPC 客户端
-	initMicro()
+	cfg, err := bootstrap.LoadConfig()
+	if err != nil {
+		log.Fatalf("加载配置失败：%v", err)
+	}
+	hystrixCfg = cfg.Hystrix
 
-	// 2. 初始化 Gin 引擎（默认模式，生产环境可改为 gin.ReleaseMode）
-	r := gin.Default()
+	ctx := context.Background()
+	shutdownTracer, err := bootstrap.InitTracer(ctx, "user.gin.gateway", cfg.Otel)
+	if err != nil {
+		log.Fatalf("初始化链路追踪失败：%v", err)
+	}
+	defer shutdownTracer(ctx)
 
-	// 3. 配置全局中间件（可选，提升接口健壮性）
-	r.Use()
+	// 1. 初始化 Etcd resolver + RPC 客户端
+	initMicro(cfg)
 
-	// 4. 注册 HTTP 路由（RESTful 风格，对应原有 RPC 接口）
-	apiGroup := r.Group("/api") // 接口前缀分组
-	{
-		// POST /client/register：用户注册（对应 RPC Register 接口）
-		apiGroup.POST("/register", registerHandler)
+	// 2. 启动Gin网关，路由注册交给routerFn，生命周期（含优雅停机）交给RunGateway
+	if err := bootstrap.RunGateway(ctx, cfg, func(r *gin.Engine) {
+		r.Use(bootstrap.GinMiddleware("user.gin.gateway"))
 
-		// GET /client/users：查询用户列表（对应 RPC List 接口）
-		// apiGroup.GET("/users", listUsersHandler)
-	}
+		apiGroup := r.Group("/api") // 接口前缀分组
+		{
+			// POST /api/register：用户注册（对应 RPC Register 接口）
+			apiGroup.POST("/register", registerHandler)
 
-	// 5. 启动 Gin 服务（监听 8080 端口，前端可访问 http://localhost:8080/api/xxx）
-	if err := r.Run(":8080"); err != nil {
-		panic("Gin 服务启动失败：" + err.Error())
+			// GET /api/users：查询用户列表（对应 RPC List 接口）
+			// apiGroup.GET("/users", listUsersHandler)
+		}
+
+		// 健康检查：供k8s探针/负载均衡判断本实例是否可用
+		r.GET("/healthz", healthzHandler)
+		r.GET("/readyz", readyzHandler)
+	}); err != nil {
+		log.Fatalf("网关运行失败：%v", err)
 	}
 }
 
@@ -102,10 +134,15 @@ func registerHandler(c *gin.Context) {
 		Email:    reqDTO.Email,
 	}
 
-	// 4. 调用 RPC 接口（添加超时控制，避免长时间阻塞）
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// 4. 调用 RPC 接口（添加超时控制，避免长时间阻塞；外层再包一层Hystrix熔断器）
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel() // 超时后释放资源
-	rpcRsp, err := userRpcClient.Register(ctx, rpcReq)
+	rpcRsp, err := callWithBreaker(ctx, hystrixCfg, "user.service", "Register",
+		func() (*user.RegisterResponse, error) {
+			return userRpcClient.Register(ctx, rpcReq)
+		},
+		registerDegradedResponse,
+	)
 
 	// 5. 处理 RPC 调用错误
 	if err != nil {
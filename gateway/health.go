@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"github.com/gin-gonic/gin"
+)
+
+// healthzHandler 进程存活探针：只要Gin在处理请求就返回200，不依赖任何外部依赖
+func healthzHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// readyzHandler 就绪探针：汇报Etcd连通性与调用user.service的熔断器状态，
+// 供k8s readinessProbe/负载均衡健康检查判断是否应该继续向本实例转发流量
+func readyzHandler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c, 2*time.Second)
+	defer cancel()
+
+	etcdReady := true
+	if _, err := etcdClient.Get(ctx, "/healthz-probe"); err != nil {
+		etcdReady = false
+	}
+
+	breakerOpen := false
+	if cb, _, err := hystrix.GetCircuit(commandName("user.service", "Register")); err == nil && cb != nil {
+		breakerOpen = cb.IsOpen()
+	}
+
+	status := http.StatusOK
+	if !etcdReady {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, gin.H{
+		"etcd_connected": etcdReady,
+		"breaker_open":   breakerOpen,
+	})
+}
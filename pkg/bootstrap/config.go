@@ -0,0 +1,16 @@
+// Package bootstrap 提供网关与服务端共用的启动骨架：配置加载、链路追踪初始化、优雅停机
+package bootstrap
+
+import (
+	"flag"
+
+	"user-service/config"
+)
+
+// LoadConfig 解析 --config 命令行参数并委托 config.LoadConfig 加载配置，
+// 未传 --config 时使用当前目录下的 config.yaml（不存在也不报错，回落到默认值，此时环境变量不生效）
+func LoadConfig() (*config.GlobalConfig, error) {
+	configPath := flag.String("config", "", "配置文件路径（默认读取当前目录下的 config.yaml）")
+	flag.Parse()
+	return config.LoadConfig(*configPath)
+}
@@ -0,0 +1,59 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+
+	"user-service/config"
+)
+
+// RunGateway 启动Gin HTTP网关并负责完整生命周期：
+//  1. 创建gin.Engine（按 cfg.Gin.Mode 设置运行模式），交给routerFn注册路由
+//  2. 监听 cfg.Gin.Port
+//  3. 收到SIGINT/SIGTERM后停止接受新连接，等待在途请求处理完毕（最多 shutdown_timeout）
+func RunGateway(ctx context.Context, cfg *config.GlobalConfig, routerFn func(*gin.Engine)) error {
+	gin.SetMode(cfg.Gin.Mode)
+	engine := gin.Default()
+	routerFn(engine)
+
+	httpServer := &http.Server{
+		Addr:    cfg.Gin.Port,
+		Handler: engine,
+	}
+
+	sigCtx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serveErrCh := make(chan error, 1)
+	go func() {
+		log.Printf("网关启动成功，监听地址：%s", cfg.Gin.Port)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErrCh <- err
+			return
+		}
+		serveErrCh <- nil
+	}()
+
+	select {
+	case err := <-serveErrCh:
+		return err
+	case <-sigCtx.Done():
+	}
+
+	log.Println("网关收到停机信号，开始优雅停机")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Service.ShutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("网关优雅停机失败：%w", err)
+	}
+	log.Println("网关已处理完在途请求，正常退出")
+	return nil
+}
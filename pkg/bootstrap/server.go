@@ -0,0 +1,88 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"os/signal"
+	"syscall"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"google.golang.org/grpc"
+
+	"user-service/config"
+	"user-service/pkg/discovery"
+)
+
+// RunServer 启动一个原生gRPC服务器并负责它的完整生命周期：
+//  1. 监听 cfg.Service.Addr，调用 registerFn 把业务handler挂到grpcServer上
+//  2. 向Etcd注册节点并保持租约
+//  3. 收到SIGINT/SIGTERM后，先从Etcd反注册，再GracefulStop（最多等待 shutdown_timeout，
+//     超时则强制Stop以避免停机流程无限挂起）；grpcServer.Serve意外返回错误时同样先反注册再返回，
+//     避免节点键一直存活到租约自然过期（最长10s）才被清理
+//
+// registerFn 由调用方提供（通常是 user.RegisterUserServiceServer 的一层包装），RunServer 不关心具体业务接口。
+func RunServer(ctx context.Context, cfg *config.GlobalConfig, etcdClient *clientv3.Client, registerFn func(*grpc.Server)) error {
+	lis, err := net.Listen("tcp", cfg.Service.Addr)
+	if err != nil {
+		return fmt.Errorf("监听端口失败：%w", err)
+	}
+
+	grpcServer := grpc.NewServer(ServerOption())
+	registerFn(grpcServer)
+
+	reg := discovery.NewRegister(etcdClient, discovery.Node{
+		Name:    cfg.Service.UserName,
+		Addr:    cfg.Service.Addr,
+		Version: cfg.Service.Version,
+		Weight:  1,
+	}, 10)
+	if err := reg.Register(ctx); err != nil {
+		return fmt.Errorf("注册服务失败：%w", err)
+	}
+
+	sigCtx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serveErrCh := make(chan error, 1)
+	go func() {
+		log.Printf("%s 启动成功，监听地址：%s", cfg.Service.UserName, cfg.Service.Addr)
+		serveErrCh <- grpcServer.Serve(lis)
+	}()
+
+	select {
+	case err := <-serveErrCh:
+		deregCtx, cancel := context.WithTimeout(context.Background(), cfg.Service.ShutdownTimeout)
+		defer cancel()
+		if deregErr := reg.Deregister(deregCtx); deregErr != nil {
+			log.Printf("反注册服务节点失败：%v", deregErr)
+		}
+		return err
+	case <-sigCtx.Done():
+	}
+
+	log.Printf("%s 收到停机信号，开始优雅停机", cfg.Service.UserName)
+
+	deregCtx, cancel := context.WithTimeout(context.Background(), cfg.Service.ShutdownTimeout)
+	defer cancel()
+	if err := reg.Deregister(deregCtx); err != nil {
+		log.Printf("反注册服务节点失败：%v", err)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		log.Printf("%s 已处理完在途请求，正常退出", cfg.Service.UserName)
+	case <-time.After(cfg.Service.ShutdownTimeout):
+		log.Printf("%s 优雅停机超时，强制关闭剩余连接", cfg.Service.UserName)
+		grpcServer.Stop()
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package bootstrap
+
+import (
+	"github.com/gin-contrib/otelgin"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+)
+
+// GinMiddleware 在Gin请求进来时创建/延续一个span，使网关入口成为链路的根span（或延续上游传来的trace）
+func GinMiddleware(serviceName string) gin.HandlerFunc {
+	return otelgin.Middleware(serviceName)
+}
+
+// ClientDialOption 供 grpc.Dial 使用，自动把Gin span的trace context通过gRPC metadata传给服务端
+func ClientDialOption() grpc.DialOption {
+	return grpc.WithStatsHandler(otelgrpc.NewClientHandler())
+}
+
+// ServerOption 供 grpc.NewServer 使用，从入站请求的metadata中还原trace context，让server handler的span挂在同一条链路上
+func ServerOption() grpc.ServerOption {
+	return grpc.StatsHandler(otelgrpc.NewServerHandler())
+}
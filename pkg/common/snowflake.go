@@ -0,0 +1,123 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const (
+	// epoch 自定义起始时间（2024-01-01 00:00:00 UTC），减少时间戳所需位数
+	epoch = int64(1704067200000)
+
+	workerIdBits   = 10
+	sequenceBits   = 12
+	maxWorkerId    = -1 ^ (-1 << workerIdBits)
+	maxSequence    = -1 ^ (-1 << sequenceBits)
+	workerIdShift  = sequenceBits
+	timestampShift = sequenceBits + workerIdBits
+
+	workerPoolPrefix = "/snowflake/workers/" // worker id 在 etcd 下的注册前缀
+	workerLeaseTTL   = 10                    // 秒
+)
+
+// Snowflake 基于雪花算法的ID生成器：1位符号位 + 41位毫秒时间戳 + 10位workerId + 12位序列号
+type Snowflake struct {
+	mu            sync.Mutex
+	workerId      int64
+	lastTimestamp int64
+	sequence      int64
+	leaseLost     int32 // 原子标记，workerId租约丢失后置1，NextId随即拒绝生成
+}
+
+// NewSnowflake 创建一个Snowflake生成器，workerId通过etcd选举获得，调用方无需关心分配细节
+func NewSnowflake(ctx context.Context, etcdClient *clientv3.Client) (*Snowflake, error) {
+	workerId, keepAliveCh, err := electWorkerId(ctx, etcdClient)
+	if err != nil {
+		return nil, fmt.Errorf("分配workerId失败：%w", err)
+	}
+	s := &Snowflake{
+		workerId:      workerId,
+		lastTimestamp: -1,
+	}
+	go s.watchLease(keepAliveCh)
+	return s, nil
+}
+
+// electWorkerId 在etcd上依次尝试Create /snowflake/workers/0..1023，第一个成功的key即为本实例的workerId，
+// 通过租约KeepAlive维持占用，租约失效（如进程退出）后该workerId会被自动释放回池中
+func electWorkerId(ctx context.Context, etcdClient *clientv3.Client) (int64, <-chan *clientv3.LeaseKeepAliveResponse, error) {
+	lease, err := etcdClient.Grant(ctx, workerLeaseTTL)
+	if err != nil {
+		return 0, nil, fmt.Errorf("创建租约失败：%w", err)
+	}
+
+	for id := int64(0); id <= maxWorkerId; id++ {
+		key := workerPoolPrefix + strconv.FormatInt(id, 10)
+		txn := etcdClient.Txn(ctx).
+			If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+			Then(clientv3.OpPut(key, strconv.FormatInt(id, 10), clientv3.WithLease(lease.ID))).
+			Else()
+		resp, err := txn.Commit()
+		if err != nil {
+			return 0, nil, fmt.Errorf("抢占workerId=%d失败：%w", id, err)
+		}
+		if resp.Succeeded {
+			keepAliveCh, err := etcdClient.KeepAlive(context.Background(), lease.ID)
+			if err != nil {
+				return 0, nil, fmt.Errorf("启动workerId租约续期失败：%w", err)
+			}
+			return id, keepAliveCh, nil
+		}
+	}
+	return 0, nil, fmt.Errorf("workerId池已耗尽（0-%d均被占用）", maxWorkerId)
+}
+
+// watchLease 持续消费续期响应以保持租约存活；keepAliveCh关闭意味着etcd已收回租约
+// （进程长时间失联、网络分区等），此时workerId可能已被其他实例重新抢占，
+// 必须置位leaseLost让NextId立即拒绝生成，避免与他人复用同一个workerId
+func (s *Snowflake) watchLease(keepAliveCh <-chan *clientv3.LeaseKeepAliveResponse) {
+	for range keepAliveCh {
+	}
+	atomic.StoreInt32(&s.leaseLost, 1)
+}
+
+// NextId 生成下一个全局唯一ID，进程内通过mutex保证同一毫秒内序列号单调递增
+func (s *Snowflake) NextId() (int64, error) {
+	if atomic.LoadInt32(&s.leaseLost) == 1 {
+		return 0, fmt.Errorf("workerId=%d的etcd租约已丢失，可能已被其他实例占用，拒绝生成ID", s.workerId)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UnixMilli()
+	if now < s.lastTimestamp {
+		// 系统时钟回拨，容忍范围内沿用上次的时间戳继续递增序列号，超出则拒绝生成
+		if s.lastTimestamp-now > 5 {
+			return 0, fmt.Errorf("检测到系统时钟回拨%dms，拒绝生成ID", s.lastTimestamp-now)
+		}
+		now = s.lastTimestamp
+	}
+
+	if now == s.lastTimestamp {
+		s.sequence = (s.sequence + 1) & maxSequence
+		if s.sequence == 0 {
+			// 同一毫秒内序列号耗尽，自旋等待下一毫秒
+			for now <= s.lastTimestamp {
+				now = time.Now().UnixMilli()
+			}
+		}
+	} else {
+		s.sequence = 0
+	}
+
+	s.lastTimestamp = now
+	id := (now-epoch)<<timestampShift | s.workerId<<workerIdShift | s.sequence
+	return id, nil
+}
@@ -0,0 +1,71 @@
+package common
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// TestSnowflake_NextId_Unique 模拟8个etcd选举出不同workerId(0..7)的生成器实例并发生成ID，
+// 校验跨实例也不会发生碰撞（若workerId没有被正确移位进ID，这里会立刻暴露）
+func TestSnowflake_NextId_Unique(t *testing.T) {
+	const instances = 8
+	const perInstance = 100000
+
+	ids := make(chan int64, instances*perInstance)
+	var wg sync.WaitGroup
+	wg.Add(instances)
+	for workerId := int64(0); workerId < instances; workerId++ {
+		s := &Snowflake{workerId: workerId, lastTimestamp: -1}
+		go func(s *Snowflake) {
+			defer wg.Done()
+			for j := 0; j < perInstance; j++ {
+				id, err := s.NextId()
+				if err != nil {
+					t.Errorf("NextId返回错误: %v", err)
+					return
+				}
+				ids <- id
+			}
+		}(s)
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[int64]struct{}, instances*perInstance)
+	for id := range ids {
+		if _, dup := seen[id]; dup {
+			t.Fatalf("发现重复ID: %d", id)
+		}
+		seen[id] = struct{}{}
+	}
+	if len(seen) != instances*perInstance {
+		t.Fatalf("期望生成%d个ID，实际%d个", instances*perInstance, len(seen))
+	}
+}
+
+// TestSnowflake_NextId_RejectsAfterLeaseLost 模拟etcd租约续期channel被动关闭（而非Close()），
+// 验证watchLease会置位leaseLost，NextId随即拒绝生成而不是继续复用已失效的workerId
+func TestSnowflake_NextId_RejectsAfterLeaseLost(t *testing.T) {
+	s := &Snowflake{workerId: 7, lastTimestamp: -1}
+
+	if _, err := s.NextId(); err != nil {
+		t.Fatalf("租约丢失前NextId不应报错: %v", err)
+	}
+
+	keepAliveCh := make(chan *clientv3.LeaseKeepAliveResponse)
+	go s.watchLease(keepAliveCh)
+	close(keepAliveCh)
+
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&s.leaseLost) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, err := s.NextId(); err == nil {
+		t.Fatal("租约丢失后NextId应返回错误，而不是继续生成ID")
+	}
+}
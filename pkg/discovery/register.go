@@ -0,0 +1,94 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// Node 描述一个服务实例的元信息，以 JSON 形式存入 Etcd
+type Node struct {
+	Name    string `json:"name"`    // 服务名称，如 "user.service"
+	Addr    string `json:"addr"`    // 监听地址，如 "192.168.1.10:9000"
+	Version string `json:"version"` // 服务版本
+	Weight  int32  `json:"weight"`  // 负载均衡权重
+}
+
+// Register 负责把本实例写入Etcd并维持租约，替代go-micro注册中心的职责
+type Register struct {
+	client  *clientv3.Client
+	node    Node
+	ttl     int64 // 租约TTL（秒）
+	leaseID clientv3.LeaseID
+	key     string
+	cancel  context.CancelFunc
+}
+
+// NewRegister 创建一个注册器，ttl 建议 10s 左右
+func NewRegister(client *clientv3.Client, node Node, ttl int64) *Register {
+	return &Register{
+		client: client,
+		node:   node,
+		ttl:    ttl,
+		key:    fmt.Sprintf("/services/%s/%s", node.Name, node.Addr),
+	}
+}
+
+// Register 创建租约、写入节点信息并启动续约协程，续约持续到Deregister被调用；
+// ctx只用于创建租约和写入节点，取消ctx不会停止续约
+func (r *Register) Register(ctx context.Context) error {
+	lease, err := r.client.Grant(ctx, r.ttl)
+	if err != nil {
+		return fmt.Errorf("创建租约失败：%w", err)
+	}
+	r.leaseID = lease.ID
+
+	value, err := json.Marshal(r.node)
+	if err != nil {
+		return fmt.Errorf("序列化节点信息失败：%w", err)
+	}
+	if _, err := r.client.Put(ctx, r.key, string(value), clientv3.WithLease(r.leaseID)); err != nil {
+		return fmt.Errorf("写入服务节点失败：%w", err)
+	}
+
+	keepAliveCh, err := r.client.KeepAlive(context.Background(), r.leaseID)
+	if err != nil {
+		return fmt.Errorf("启动租约续期失败：%w", err)
+	}
+
+	keepAliveCtx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	go func() {
+		for {
+			select {
+			case <-keepAliveCtx.Done():
+				return
+			case _, ok := <-keepAliveCh:
+				if !ok {
+					// 租约被撤销或连接断开，续期通道关闭
+					return
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Deregister 撤销租约并删除节点键，服务下线时调用
+func (r *Register) Deregister(ctx context.Context) error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	if _, err := r.client.Delete(ctx, r.key); err != nil {
+		return fmt.Errorf("删除服务节点失败：%w", err)
+	}
+	if r.leaseID != 0 {
+		if _, err := r.client.Revoke(ctx, r.leaseID); err != nil {
+			return fmt.Errorf("撤销租约失败：%w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"google.golang.org/grpc/resolver"
+)
+
+const (
+	watchMinBackoff = 500 * time.Millisecond
+	watchMaxBackoff = 30 * time.Second
+)
+
+// Scheme 是本resolver注册的gRPC target scheme，使用方式：grpc.Dial("etcd:///user.service", ...)
+const Scheme = "etcd"
+
+// Builder 实现 resolver.Builder，负责根据 target 创建一个 etcd Resolver
+type Builder struct {
+	client *clientv3.Client
+}
+
+// NewBuilder 创建一个基于 etcd 的 resolver.Builder，需配合 resolver.Register 使用
+func NewBuilder(client *clientv3.Client) *Builder {
+	return &Builder{client: client}
+}
+
+func (b *Builder) Scheme() string {
+	return Scheme
+}
+
+// Build 在 grpc.Dial 时被调用，target.Endpoint() 即服务名（如 "user.service"）
+func (b *Builder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &Resolver{
+		client: b.client,
+		cc:     cc,
+		prefix: "/services/" + target.Endpoint() + "/",
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	if err := r.resolveNow(); err != nil {
+		cancel()
+		return nil, err
+	}
+	go r.watch()
+	return r, nil
+}
+
+// Resolver 实现 resolver.Resolver，监听 etcd 前缀变化并更新 gRPC 的连接地址列表
+type Resolver struct {
+	client *clientv3.Client
+	cc     resolver.ClientConn
+	prefix string
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// ResolveNow 是 grpc 在需要重新解析时的回调，这里直接忽略（watch 协程已经在持续更新）
+func (r *Resolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+func (r *Resolver) Close() {
+	r.cancel()
+}
+
+// resolveNow 做一次全量 Get，拿到当前所有存活节点；同一地址可能因注册key残留、
+// 重复注册等原因在etcd中出现多条记录，这里按Addr去重后再交给gRPC，避免下游看到重复地址
+func (r *Resolver) resolveNow() error {
+	resp, err := r.client.Get(r.ctx, r.prefix, clientv3.WithPrefix())
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]struct{}, len(resp.Kvs))
+	addrs := make([]resolver.Address, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var node Node
+		if err := json.Unmarshal(kv.Value, &node); err != nil {
+			log.Printf("discovery: 解析节点信息失败 key=%s: %v", kv.Key, err)
+			continue
+		}
+		if _, dup := seen[node.Addr]; dup {
+			continue
+		}
+		seen[node.Addr] = struct{}{}
+		addrs = append(addrs, resolver.Address{Addr: node.Addr})
+	}
+	return r.cc.UpdateState(resolver.State{Addresses: addrs})
+}
+
+// watch 持续监听前缀下的新增/删除，每次变化都重新拉取全量列表并更新 ClientConn。
+// watch channel 可能因网络抖动、etcd leader切换等原因被动关闭（而非Close()导致的ctx取消），
+// 此时需要带退避地重新发起Watch，否则resolver会冻结在最后一次已知的地址列表上
+func (r *Resolver) watch() {
+	backoff := watchMinBackoff
+	for {
+		watchCh := r.client.Watch(r.ctx, r.prefix, clientv3.WithPrefix())
+		closed := r.consume(watchCh)
+		if !closed {
+			return // ctx已取消（Close()被调用），无需重连
+		}
+
+		log.Printf("discovery: watch通道意外关闭，%s后重连前缀=%s", backoff, r.prefix)
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > watchMaxBackoff {
+			backoff = watchMaxBackoff
+		}
+	}
+}
+
+// consume 消费单次Watch建立的channel直到其关闭或ctx取消，期间每次变化都重新拉取全量列表
+func (r *Resolver) consume(watchCh clientv3.WatchChan) (channelClosed bool) {
+	for {
+		select {
+		case <-r.ctx.Done():
+			return false
+		case _, ok := <-watchCh:
+			if !ok {
+				return true
+			}
+			if err := r.resolveNow(); err != nil {
+				log.Printf("discovery: 刷新服务列表失败: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/tests/v3/integration"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/serviceconfig"
+)
+
+// fakeClientConn 记录resolver.UpdateState的每次调用，用于断言最终的地址集合
+type fakeClientConn struct {
+	resolver.ClientConn
+	mu      sync.Mutex
+	updates []resolver.State
+}
+
+func (f *fakeClientConn) UpdateState(state resolver.State) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updates = append(f.updates, state)
+	return nil
+}
+
+func (f *fakeClientConn) ReportError(error) {}
+
+func (f *fakeClientConn) ParseServiceConfig(string) *serviceconfig.ParseResult {
+	return &serviceconfig.ParseResult{}
+}
+
+func (f *fakeClientConn) latest() resolver.State {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.updates[len(f.updates)-1]
+}
+
+func (f *fakeClientConn) waitUpdates(n int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		f.mu.Lock()
+		count := len(f.updates)
+		f.mu.Unlock()
+		if count >= n {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+// TestRegisterAndResolver_DiscoversAndDedupsNodes 用内嵌etcd集群验证：
+// Register写入的节点能被Resolver发现，且重复写入同一地址不会产生重复的resolver.Address
+func TestRegisterAndResolver_DiscoversAndDedupsNodes(t *testing.T) {
+	cluster := integration.NewClusterV3(t, &integration.ClusterConfig{Size: 1})
+	defer cluster.Terminate(t)
+	client := cluster.RandClient()
+
+	node := Node{Name: "user.service", Addr: "10.0.0.1:9000", Version: "v1", Weight: 1}
+	reg := NewRegister(client, node, 5)
+	if err := reg.Register(context.Background()); err != nil {
+		t.Fatalf("Register失败: %v", err)
+	}
+	defer reg.Deregister(context.Background())
+
+	// 模拟残留的重复key，指向同一个Addr，验证resolveNow去重
+	staleValue, err := json.Marshal(node)
+	if err != nil {
+		t.Fatalf("序列化节点信息失败: %v", err)
+	}
+	if _, err := client.Put(context.Background(), "/services/user.service/stale-dup", string(staleValue)); err != nil {
+		t.Fatalf("写入重复节点失败: %v", err)
+	}
+
+	cc := &fakeClientConn{}
+	builder := NewBuilder(client)
+	r, err := builder.Build(
+		resolver.Target{URL: url.URL{Scheme: Scheme, Path: "/user.service"}},
+		cc,
+		resolver.BuildOptions{},
+	)
+	if err != nil {
+		t.Fatalf("Build失败: %v", err)
+	}
+	defer r.Close()
+
+	if !cc.waitUpdates(1, time.Second) {
+		t.Fatal("未收到任何UpdateState")
+	}
+	state := cc.latest()
+	if len(state.Addresses) != 1 {
+		t.Fatalf("重复地址应被去重为1个，实际%d个: %+v", len(state.Addresses), state.Addresses)
+	}
+	if state.Addresses[0].Addr != node.Addr {
+		t.Fatalf("地址不匹配，期望%s，实际%s", node.Addr, state.Addresses[0].Addr)
+	}
+}
@@ -0,0 +1,28 @@
+// Package dlock 提供统一的分布式锁抽象，屏蔽底层基于Etcd或Redis的实现差异
+package dlock
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrLockHeld 表示锁被其他持有者占用；TryLock在等待窗口内未抢到锁时返回ok=false、err=nil，不返回该错误
+	ErrLockHeld = errors.New("dlock: 锁已被占用")
+	// ErrSessionLost 表示锁在持有期间会话/租约意外失效（如etcd会话过期、网络分区导致续约失败）
+	ErrSessionLost = errors.New("dlock: 锁会话已失效")
+)
+
+// Unlocker 代表一次已获取的锁，调用方用它来释放锁
+type Unlocker interface {
+	Unlock(ctx context.Context) error
+}
+
+// Locker 是分布式锁的统一接口，Etcd与Redis实现均满足该接口
+type Locker interface {
+	// Lock 阻塞直到获取成功或ctx被取消
+	Lock(ctx context.Context, key string) (Unlocker, error)
+	// TryLock 在wait时间窗口内尝试获取锁，超时未获取到返回 ok=false（而非error）
+	TryLock(ctx context.Context, key string, wait time.Duration) (Unlocker, bool, error)
+}
@@ -0,0 +1,109 @@
+package dlock
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+const etcdBackend = "etcd"
+
+// EtcdLocker 基于Etcd concurrency.Mutex实现的Locker，锁的生命周期绑定一个concurrency.Session，
+// Session内部已经以ttl/3的间隔自动续租，这里额外起一个watchdog监听会话是否意外失效
+type EtcdLocker struct {
+	client *clientv3.Client
+	ttl    int // 会话租约TTL（秒）
+}
+
+// NewEtcdLocker 创建基于Etcd的分布式锁，ttl建议10-30秒
+func NewEtcdLocker(client *clientv3.Client, ttl int) *EtcdLocker {
+	return &EtcdLocker{client: client, ttl: ttl}
+}
+
+// etcdUnlocker 持有一次加锁的会话与互斥锁，Unlock时释放两者
+type etcdUnlocker struct {
+	session      *concurrency.Session
+	mutex        *concurrency.Mutex
+	watchdogDone chan struct{}
+}
+
+func (l *EtcdLocker) Lock(ctx context.Context, key string) (Unlocker, error) {
+	session, mutex, err := l.acquire(ctx, key, func(m *concurrency.Mutex) error {
+		return m.Lock(ctx)
+	})
+	if err != nil {
+		observeAcquire(etcdBackend, false)
+		return nil, err
+	}
+	observeAcquire(etcdBackend, true)
+	return l.wrap(session, mutex), nil
+}
+
+func (l *EtcdLocker) TryLock(ctx context.Context, key string, wait time.Duration) (Unlocker, bool, error) {
+	waitCtx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	session, mutex, err := l.acquire(ctx, key, func(m *concurrency.Mutex) error {
+		return m.Lock(waitCtx)
+	})
+	if err != nil {
+		observeAcquire(etcdBackend, false)
+		if waitCtx.Err() != nil {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	observeAcquire(etcdBackend, true)
+	return l.wrap(session, mutex), true, nil
+}
+
+func (l *EtcdLocker) acquire(ctx context.Context, key string, lockFn func(*concurrency.Mutex) error) (*concurrency.Session, *concurrency.Mutex, error) {
+	session, err := concurrency.NewSession(l.client, concurrency.WithTTL(l.ttl))
+	if err != nil {
+		return nil, nil, fmt.Errorf("创建etcd会话失败：%w", err)
+	}
+
+	mutex := concurrency.NewMutex(session, key)
+	if err := lockFn(mutex); err != nil {
+		session.Close()
+		return nil, nil, fmt.Errorf("获取etcd锁失败：%w", err)
+	}
+	return session, mutex, nil
+}
+
+// wrap 包装会话与互斥锁，并启动watchdog监听会话是否在持有期间意外失效
+func (l *EtcdLocker) wrap(session *concurrency.Session, mutex *concurrency.Mutex) *etcdUnlocker {
+	u := &etcdUnlocker{
+		session:      session,
+		mutex:        mutex,
+		watchdogDone: make(chan struct{}),
+	}
+	go u.watchdog()
+	return u
+}
+
+func (u *etcdUnlocker) watchdog() {
+	select {
+	case <-u.session.Done():
+		// 会话在Unlock之前就已失效（如租约被意外撤销、etcd不可达超过TTL）
+		observeRenew(etcdBackend, false)
+		log.Printf("dlock: etcd会话意外失效：%v", ErrSessionLost)
+	case <-u.watchdogDone:
+		observeRenew(etcdBackend, true)
+	}
+}
+
+func (u *etcdUnlocker) Unlock(ctx context.Context) error {
+	close(u.watchdogDone)
+	defer u.session.Close()
+	if err := u.mutex.Unlock(ctx); err != nil {
+		observeRelease(etcdBackend, false)
+		return fmt.Errorf("释放etcd锁失败：%w", err)
+	}
+	observeRelease(etcdBackend, true)
+	return nil
+}
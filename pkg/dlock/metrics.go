@@ -0,0 +1,44 @@
+package dlock
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// 按backend（etcd/redis）和result（success/fail）维度统计锁的获取、释放、续约情况
+var (
+	acquireTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dlock_acquire_total",
+		Help: "分布式锁获取次数",
+	}, []string{"backend", "result"})
+
+	releaseTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dlock_release_total",
+		Help: "分布式锁释放次数",
+	}, []string{"backend", "result"})
+
+	renewTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dlock_renew_total",
+		Help: "分布式锁续约次数",
+	}, []string{"backend", "result"})
+)
+
+func init() {
+	prometheus.MustRegister(acquireTotal, releaseTotal, renewTotal)
+}
+
+func observeAcquire(backend string, ok bool) {
+	acquireTotal.WithLabelValues(backend, resultLabel(ok)).Inc()
+}
+
+func observeRelease(backend string, ok bool) {
+	releaseTotal.WithLabelValues(backend, resultLabel(ok)).Inc()
+}
+
+func observeRenew(backend string, ok bool) {
+	renewTotal.WithLabelValues(backend, resultLabel(ok)).Inc()
+}
+
+func resultLabel(ok bool) string {
+	if ok {
+		return "success"
+	}
+	return "fail"
+}
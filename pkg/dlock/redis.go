@@ -0,0 +1,216 @@
+package dlock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const redisBackend = "redis"
+
+// clockDriftFactor 时钟漂移系数，参考Redlock论文取0.01
+const clockDriftFactor = 0.01
+
+// releaseScript 只有存储的随机token仍与调用方一致时才删除key，避免误删其他客户端续约后持有的锁
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+    return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// renewScript 续约：只有token仍匹配时才延长过期时间
+var renewScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+    return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// RedisLocker 基于Redlock算法实现的Locker：在N>=3个独立Redis节点上各自SET NX PX，
+// 当在多数节点上于漂移校正后的有效期内获取成功时，认为锁获取成功
+type RedisLocker struct {
+	clients []*redis.Client
+	ttl     time.Duration
+}
+
+// NewRedisLocker 创建Redlock实现，clients应为至少3个相互独立（无主从复制关系）的Redis节点
+func NewRedisLocker(clients []*redis.Client, ttl time.Duration) (*RedisLocker, error) {
+	if len(clients) < 3 {
+		return nil, fmt.Errorf("redlock要求至少3个独立Redis节点，实际传入%d个", len(clients))
+	}
+	return &RedisLocker{clients: clients, ttl: ttl}, nil
+}
+
+type redisUnlocker struct {
+	locker    *RedisLocker
+	key       string
+	token     string
+	renewDone chan struct{}
+}
+
+func (l *RedisLocker) Lock(ctx context.Context, key string) (Unlocker, error) {
+	for {
+		u, ok, err := l.TryLock(ctx, key, 0)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return u, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+func (l *RedisLocker) TryLock(ctx context.Context, key string, wait time.Duration) (Unlocker, bool, error) {
+	deadline := time.Now().Add(wait)
+	attemptTimeout := l.attemptTimeout(wait)
+	for {
+		token, acquired, err := l.tryAcquireOnce(ctx, key, attemptTimeout)
+		if err != nil {
+			observeAcquire(redisBackend, false)
+			return nil, false, err
+		}
+		if acquired {
+			observeAcquire(redisBackend, true)
+			u := &redisUnlocker{locker: l, key: key, token: token, renewDone: make(chan struct{})}
+			go u.watchdog()
+			return u, true, nil
+		}
+		if wait <= 0 || time.Now().After(deadline) {
+			observeAcquire(redisBackend, false)
+			return nil, false, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
+
+// attemptTimeout 计算单次tryAcquireOnce允许占用的时长：优先不超过调用方的wait预算，
+// 否则退化为ttl/3，避免单个卡住/分区的节点把一次加锁尝试拖到超出调用方预期之外
+func (l *RedisLocker) attemptTimeout(wait time.Duration) time.Duration {
+	maxAttempt := l.ttl / 3
+	if wait > 0 && wait < maxAttempt {
+		return wait
+	}
+	return maxAttempt
+}
+
+// tryAcquireOnce 在所有节点上并发尝试SET NX PX，统计在漂移校正后的有效期内拿到多数节点的情况；
+// attemptTimeout 限定本次尝试的上限耗时，派生出的ctx会在超时后让还没返回的节点调用提前失败
+func (l *RedisLocker) tryAcquireOnce(ctx context.Context, key string, attemptTimeout time.Duration) (string, bool, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", false, fmt.Errorf("生成锁token失败：%w", err)
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, attemptTimeout)
+	defer cancel()
+
+	start := time.Now()
+	quorum := len(l.clients)/2 + 1
+	acquiredCount := 0
+
+	results := make(chan bool, len(l.clients))
+	var wg sync.WaitGroup
+	for _, c := range l.clients {
+		wg.Add(1)
+		go func(c *redis.Client) {
+			defer wg.Done()
+			ok, err := c.SetNX(attemptCtx, key, token, l.ttl).Result()
+			if err != nil {
+				results <- false // 单节点失败不影响整体判断，最终按quorum裁决
+				return
+			}
+			results <- ok
+		}(c)
+	}
+	wg.Wait()
+	close(results)
+	for ok := range results {
+		if ok {
+			acquiredCount++
+		}
+	}
+
+	elapsed := time.Since(start)
+	drift := time.Duration(float64(l.ttl)*clockDriftFactor) + 2*time.Millisecond
+	validity := l.ttl - elapsed - drift
+
+	if acquiredCount >= quorum && validity > 0 {
+		return token, true, nil
+	}
+
+	// 未达多数或有效期已耗尽，回滚已获取的节点避免残留锁
+	l.releaseAll(context.Background(), key, token)
+	return "", false, nil
+}
+
+func (l *RedisLocker) releaseAll(ctx context.Context, key, token string) {
+	for _, c := range l.clients {
+		releaseScript.Run(ctx, c, []string{key}, token)
+	}
+}
+
+// watchdog 每隔ttl/3续约一次，直到Unlock被调用；多数节点续约失败则记为失效并停止续约
+func (u *redisUnlocker) watchdog() {
+	ticker := time.NewTicker(u.locker.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-u.renewDone:
+			return
+		case <-ticker.C:
+			if !u.renewOnce() {
+				log.Printf("dlock: redis锁续约失败（多数节点未确认），视为会话失效：%v", ErrSessionLost)
+				return
+			}
+		}
+	}
+}
+
+func (u *redisUnlocker) renewOnce() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), u.locker.ttl/3)
+	defer cancel()
+
+	quorum := len(u.locker.clients)/2 + 1
+	renewed := 0
+	ttlMs := u.locker.ttl.Milliseconds()
+	for _, c := range u.locker.clients {
+		res, err := renewScript.Run(ctx, c, []string{u.key}, u.token, ttlMs).Int()
+		if err == nil && res == 1 {
+			renewed++
+		}
+	}
+	ok := renewed >= quorum
+	observeRenew(redisBackend, ok)
+	return ok
+}
+
+func (u *redisUnlocker) Unlock(ctx context.Context) error {
+	close(u.renewDone)
+	u.locker.releaseAll(ctx, u.key, u.token)
+	observeRelease(redisBackend, true)
+	return nil
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
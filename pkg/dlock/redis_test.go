@@ -0,0 +1,85 @@
+package dlock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newMiniredisClients(t *testing.T, n int) []*redis.Client {
+	t.Helper()
+	clients := make([]*redis.Client, n)
+	for i := 0; i < n; i++ {
+		mr := miniredis.RunT(t)
+		clients[i] = redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	}
+	return clients
+}
+
+// TestRedisLocker_TryLock_QuorumWithOneNodeDown 3个节点中1个宕机，仍应达到多数(2/3)并成功加锁
+func TestRedisLocker_TryLock_QuorumWithOneNodeDown(t *testing.T) {
+	clients := newMiniredisClients(t, 3)
+	clients[2].Close() // 模拟第三个节点不可达
+
+	locker, err := NewRedisLocker(clients, time.Second)
+	if err != nil {
+		t.Fatalf("NewRedisLocker失败: %v", err)
+	}
+
+	u, ok, err := locker.TryLock(context.Background(), "order:123", time.Second)
+	if err != nil {
+		t.Fatalf("TryLock返回错误: %v", err)
+	}
+	if !ok {
+		t.Fatal("2/3节点可用时应达成多数并加锁成功")
+	}
+	defer u.Unlock(context.Background())
+}
+
+// TestRedisLocker_TryLock_FailsWithoutQuorum 3个节点中2个宕机，不足多数，应以ok=false而非error返回
+func TestRedisLocker_TryLock_FailsWithoutQuorum(t *testing.T) {
+	clients := newMiniredisClients(t, 3)
+	clients[1].Close()
+	clients[2].Close()
+
+	locker, err := NewRedisLocker(clients, time.Second)
+	if err != nil {
+		t.Fatalf("NewRedisLocker失败: %v", err)
+	}
+
+	_, ok, err := locker.TryLock(context.Background(), "order:123", 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("未达多数应返回ok=false而非error: %v", err)
+	}
+	if ok {
+		t.Fatal("只有1/3节点可用时不应判定加锁成功")
+	}
+}
+
+// TestRedisLocker_TryLock_AlreadyHeld 锁已被其他token持有时，TryLock应在等待窗口耗尽后
+// 以ok=false、err=nil返回（区别于节点不可达等真正的error）
+func TestRedisLocker_TryLock_AlreadyHeld(t *testing.T) {
+	clients := newMiniredisClients(t, 3)
+
+	locker, err := NewRedisLocker(clients, time.Second)
+	if err != nil {
+		t.Fatalf("NewRedisLocker失败: %v", err)
+	}
+
+	first, ok, err := locker.TryLock(context.Background(), "order:123", time.Second)
+	if err != nil || !ok {
+		t.Fatalf("首次加锁应成功: ok=%v err=%v", ok, err)
+	}
+	defer first.Unlock(context.Background())
+
+	_, ok, err = locker.TryLock(context.Background(), "order:123", 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("锁已被占用时应返回ok=false而非error: %v", err)
+	}
+	if ok {
+		t.Fatal("锁已被占用时第二次TryLock不应成功")
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import "sync"
+
+// UserRepository 抽象用户存储，使 UserServiceImpl 不依赖具体存储实现，便于测试时替换为桩实现
+type UserRepository interface {
+	Exists(username string) bool
+	Save(username string) error
+}
+
+// memoryUserRepository 进程内存实现（goroutine-safe），替代此前未加锁的 userDB map
+type memoryUserRepository struct {
+	mu    sync.RWMutex
+	users map[string]bool
+}
+
+// NewMemoryUserRepository 创建一个空的内存用户仓库
+func NewMemoryUserRepository() UserRepository {
+	return &memoryUserRepository{users: make(map[string]bool)}
+}
+
+func (r *memoryUserRepository) Exists(username string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.users[username]
+}
+
+func (r *memoryUserRepository) Save(username string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[username] = true
+	return nil
+}
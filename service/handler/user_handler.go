@@ -3,79 +3,102 @@ package main
 import (
 	"context"
 	"fmt"
-	"github.com/go-micro/plugins/v4/registry/etcd"
-	"github.com/go-micro/plugins/v4/server/grpc"
-	"go-micro.dev/v4"
-	"go-micro.dev/v4/logger"
-	"go-micro.dev/v4/registry"
-	"math/rand"
+	"log"
 	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"google.golang.org/grpc"
+
+	"user-service/pkg/bootstrap"
+	"user-service/pkg/common"
+	"user-service/pkg/dlock"
 	"user-service/proto/user"
 )
 
 // 1. 定义服务端结构体（用于绑定接口方法）
-type UserServiceImpl struct{}
-
-// 2. 模拟内存数据库（存储已注册用户，避免重复注册）
-var userDB = make(map[string]bool) // key：用户名，value：是否已注册
+type UserServiceImpl struct {
+	user.UnimplementedUserServiceServer
+	repo   UserRepository    // 用户存储，默认是内存实现，测试时可替换为桩实现
+	idGen  *common.Snowflake // 雪花算法ID生成器，workerId通过etcd选举获得
+	locker dlock.Locker      // 分布式锁，用于保护"查重-写入"这段临界区
+}
 
-// 3. 实现 Protobuf 定义的 Register 接口方法
-// 入参：context.Context（微服务必备，传递元数据、超时等）、*user.RegisterRequest（注册请求）
-// 返回：*user.RegisterResponse（注册响应）、error（错误信息）
-func (u *UserServiceImpl) Register(ctx context.Context, req *user.RegisterRequest, rsp *user.RegisterResponse) error {
+// 2. 实现 Protobuf 定义的 Register 接口方法（原生 gRPC 签名：入参请求，出参响应+error）
+func (u *UserServiceImpl) Register(ctx context.Context, req *user.RegisterRequest) (*user.RegisterResponse, error) {
 	// 步骤 1：参数校验
 	if req.Username == "" || req.Password == "" {
-		rsp.Code = 1
-		rsp.Message = "用户名和密码不能为空"
-		return nil
+		return &user.RegisterResponse{Code: 1, Message: "用户名和密码不能为空"}, nil
+	}
+	log.Printf("用户注册请求：用户名=%s，密码=%s，邮箱=%s", req.Username, req.Password, req.Email)
+
+	// 步骤 2：对该用户名加分布式锁，避免"查重"和"写入"之间的TOCTOU竞态（多实例并发注册同名用户）
+	lockKey := "/lock/user/register/" + req.Username
+	unlock, ok, err := u.locker.TryLock(ctx, lockKey, 3*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("获取注册锁失败：%w", err)
 	}
-	logger.Infof("用户注册请求：用户名=%s，密码=%s，邮箱=%s", req.Username, req.Password, req.Email)
-	// 步骤 2：检查用户名是否已注册
-	if _, exists := userDB[req.Username]; exists {
-		rsp.Code = 1
-		rsp.Message = "用户名已存在，请更换用户名"
-		return nil
+	if !ok {
+		return &user.RegisterResponse{Code: 1, Message: "注册请求处理中，请稍后重试"}, nil
 	}
+	defer unlock.Unlock(context.Background())
 
-	// 步骤 3：生成用户ID（模拟唯一ID，生产环境可用雪花算法）
-	rand.Seed(time.Now().UnixNano())
-	userId := fmt.Sprintf("USER_%d%06d", time.Now().Unix(), rand.Intn(999999))
+	// 步骤 3：检查用户名是否已注册
+	if u.repo.Exists(req.Username) {
+		return &user.RegisterResponse{Code: 1, Message: "用户名已存在，请更换用户名"}, nil
+	}
 
-	// 步骤 4：存入内存数据库
-	userDB[req.Username] = true
+	// 步骤 4：生成用户ID（雪花算法，进程内单调递增且跨实例不冲突）
+	snowflakeId, err := u.idGen.NextId()
+	if err != nil {
+		return nil, fmt.Errorf("生成用户ID失败：%w", err)
+	}
+	userId := fmt.Sprintf("USER_%d", snowflakeId)
 
-	// 步骤 5：构造响应结果
-	rsp.Code = 0
-	rsp.Message = "注册成功"
-	rsp.UserId = userId
+	// 步骤 5：存入用户仓库
+	if err := u.repo.Save(req.Username); err != nil {
+		return nil, fmt.Errorf("保存用户失败：%w", err)
+	}
 
-	logger.Infof("用户注册成功：用户名=%s，用户ID=%s", req.Username, userId)
-	return nil
+	log.Printf("用户注册成功：用户名=%s，用户ID=%s", req.Username, userId)
+	return &user.RegisterResponse{Code: 0, Message: "注册成功", UserId: userId}, nil
 }
 
 func main() {
-	// 步骤 1：创建微服务实例
-	etcdReg := etcd.NewRegistry(
-		registry.Addrs(fmt.Sprintf("%s:%s", "127.0.0.1", "2379")),
-	)
-	service := micro.NewService(
-		micro.Server(grpc.NewServer()),
-		micro.Name("user.service"), // 服务名称（客户端通过该名称调用）
-		micro.Version("v1.0.0"),    // 服务版本（可选）
-		micro.Registry(etcdReg),    // etcd 注册中心
-	)
+	cfg, err := bootstrap.LoadConfig()
+	if err != nil {
+		log.Fatalf("加载配置失败：%v", err)
+	}
 
-	// 步骤 2：初始化微服务（加载配置、服务发现等）
-	service.Init()
+	ctx := context.Background()
+	shutdownTracer, err := bootstrap.InitTracer(ctx, cfg.Service.UserName, cfg.Otel)
+	if err != nil {
+		log.Fatalf("初始化链路追踪失败：%v", err)
+	}
+	defer shutdownTracer(ctx)
 
-	// 步骤 3：注册 UserService 接口到微服务
-	err := user.RegisterUserServiceHandler(service.Server(), &UserServiceImpl{})
+	etcdClient, err := clientv3.New(clientv3.Config{
+		Endpoints:   cfg.Etcd.Adders,
+		DialTimeout: 5 * time.Second,
+	})
 	if err != nil {
-		logger.Fatalf("注册服务失败：%v", err)
+		log.Fatalf("创建Etcd客户端失败：%v", err)
+	}
+	defer etcdClient.Close()
+
+	idGen, err := common.NewSnowflake(ctx, etcdClient)
+	if err != nil {
+		log.Fatalf("初始化雪花算法ID生成器失败：%v", err)
+	}
+	locker := dlock.NewEtcdLocker(etcdClient, 10)
+	impl := &UserServiceImpl{
+		repo:   NewMemoryUserRepository(),
+		idGen:  idGen,
+		locker: locker,
 	}
 
-	// 步骤 4：启动微服务
-	if err = service.Run(); err != nil {
-		logger.Fatalf("启动服务失败：%v", err)
+	if err := bootstrap.RunServer(ctx, cfg, etcdClient, func(s *grpc.Server) {
+		user.RegisterUserServiceServer(s, impl)
+	}); err != nil {
+		log.Fatalf("启动服务失败：%v", err)
 	}
 }